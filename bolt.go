@@ -0,0 +1,38 @@
+package urlshort
+
+import (
+	"net/http"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltHandler returns an http.Handler that resolves r.URL.Path to a
+// destination by looking it up in bucket of db, falling back to
+// fallbackHandler when the path has no entry (or bucket does not exist).
+// Each request opens a read-only transaction, which BoltDB serves from
+// its mmap'd file without blocking writers.
+//
+// The returned handler is wrapped with Instrument, so its redirects and
+// fallbacks count toward this package's metrics, access logs, and
+// Stats/AdminHandler.
+func BoltHandler(db *bolt.DB, bucket string, fallbackHandler http.Handler) *InstrumentedHandler {
+	resolve := func(rw http.ResponseWriter, r *http.Request) {
+		var destination string
+		db.View(func(tx *bolt.Tx) error {
+			b := tx.Bucket([]byte(bucket))
+			if b == nil {
+				return nil
+			}
+			if v := b.Get([]byte(r.URL.Path)); v != nil {
+				destination = string(v)
+			}
+			return nil
+		})
+		if destination != "" {
+			http.Redirect(rw, r, destination, http.StatusFound)
+			return
+		}
+		fallbackHandler.ServeHTTP(rw, r)
+	}
+	return Instrument(http.HandlerFunc(resolve))
+}
@@ -0,0 +1,73 @@
+package urlshort
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func openTestBoltDB(t *testing.T, bucket string, entries map[string]string) *bolt.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "urlshort.db")
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatalf("bolt.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		for k, v := range entries {
+			if err := b.Put([]byte(k), []byte(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("seeding bucket %q: %v", bucket, err)
+	}
+	return db
+}
+
+func TestBoltHandler_Hit(t *testing.T) {
+	db := openTestBoltDB(t, "redirects", map[string]string{"/a": "https://example.com/a"})
+	h := BoltHandler(db, "redirects", http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rw.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusFound)
+	}
+	if got := rw.Header().Get("Location"); got != "https://example.com/a" {
+		t.Fatalf("Location = %q, want %q", got, "https://example.com/a")
+	}
+}
+
+func TestBoltHandler_MissFallsThrough(t *testing.T) {
+	db := openTestBoltDB(t, "redirects", map[string]string{"/a": "https://example.com/a"})
+	h := BoltHandler(db, "redirects", http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rw.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusNotFound)
+	}
+}
+
+func TestBoltHandler_MissingBucketFallsThrough(t *testing.T) {
+	db := openTestBoltDB(t, "redirects", map[string]string{"/a": "https://example.com/a"})
+	h := BoltHandler(db, "does-not-exist", http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rw.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,55 @@
+package urlshort
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+)
+
+// CSVHandler will parse the provided CSV and then return an http.Handler
+// that will attempt to map any paths to their corresponding URL. If the
+// path is not provided in the CSV, then the fallback http.Handler will
+// be called instead.
+//
+// CSV is expected to have a header row naming the "path" and "url"
+// columns, e.g.:
+//
+//	path,url
+//	/some-path,https://www.some-url.com/demo
+func CSVHandler(data []byte, fallbackHandler http.Handler) (http.Handler, error) {
+	return FormatHandler(data, "csv", fallbackHandler)
+}
+
+func parseCSVToPathURL(data []byte) ([]PathURL, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	pathCol, urlCol := -1, -1
+	for i, name := range records[0] {
+		switch name {
+		case "path":
+			pathCol = i
+		case "url":
+			urlCol = i
+		}
+	}
+	if pathCol == -1 || urlCol == -1 {
+		return nil, fmt.Errorf(`urlshort: csv: header must contain "path" and "url" columns`)
+	}
+
+	pathURLs := make([]PathURL, 0, len(records)-1)
+	for _, record := range records[1:] {
+		pathURLs = append(pathURLs, PathURL{Path: record[pathCol], URL: record[urlCol]})
+	}
+	return pathURLs, nil
+}
+
+func init() {
+	RegisterDecoder("csv", DecoderFunc(parseCSVToPathURL))
+}
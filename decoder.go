@@ -0,0 +1,58 @@
+package urlshort
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Decoder parses raw configuration bytes into a slice of PathURL entries.
+type Decoder interface {
+	Decode(data []byte) ([]PathURL, error)
+}
+
+// DecoderFunc adapts an ordinary function to a Decoder.
+type DecoderFunc func(data []byte) ([]PathURL, error)
+
+// Decode implements Decoder.
+func (f DecoderFunc) Decode(data []byte) ([]PathURL, error) {
+	return f(data)
+}
+
+var decoders = map[string]Decoder{}
+
+// RegisterDecoder registers d to handle the given format name, e.g.
+// RegisterDecoder("toml", myTOMLDecoder{}). Registering a format a second
+// time overwrites the previously registered decoder. Format names are
+// matched as passed to SourceHandler/FormatHandler, so keep them
+// lowercase (as the built-in "json", "yaml" and "yml" decoders are).
+func RegisterDecoder(name string, d Decoder) {
+	decoders[name] = d
+}
+
+// FormatHandler decodes data using the Decoder registered for format and
+// returns an http.Handler that serves redirects from the resulting map,
+// falling back to fallbackHandler for any path it does not recognize.
+func FormatHandler(data []byte, format string, fallbackHandler http.Handler) (http.Handler, error) {
+	pathURLs, err := decode(data, format)
+	if err != nil {
+		return nil, err
+	}
+	pathsToURLs := pathSliceToMapConversion(pathURLs)
+	return MapHandler(pathsToURLs, fallbackHandler), nil
+}
+
+// decode looks up the Decoder registered for format and uses it to parse
+// data.
+func decode(data []byte, format string) ([]PathURL, error) {
+	d, ok := decoders[format]
+	if !ok {
+		return nil, fmt.Errorf("urlshort: no decoder registered for format %q", format)
+	}
+	return d.Decode(data)
+}
+
+func init() {
+	RegisterDecoder("json", DecoderFunc(parseJSONToPathURL))
+	RegisterDecoder("yaml", DecoderFunc(parseYAMLToPathURL))
+	RegisterDecoder("yml", DecoderFunc(parseYAMLToPathURL))
+}
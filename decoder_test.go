@@ -0,0 +1,53 @@
+package urlshort
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDecode_UnknownFormat(t *testing.T) {
+	if _, err := decode([]byte(`[]`), "xml"); err == nil {
+		t.Fatal("expected an error for an unregistered format, got nil")
+	}
+}
+
+func TestDecode_RegisterDecoder(t *testing.T) {
+	const format = "test-register"
+	RegisterDecoder(format, DecoderFunc(func(data []byte) ([]PathURL, error) {
+		return []PathURL{{Path: "/a", URL: "https://example.com"}}, nil
+	}))
+
+	pathURLs, err := decode([]byte("irrelevant"), format)
+	if err != nil {
+		t.Fatalf("decode returned unexpected error: %v", err)
+	}
+	if len(pathURLs) != 1 || pathURLs[0].Path != "/a" {
+		t.Fatalf("decode = %+v, want a single /a entry", pathURLs)
+	}
+}
+
+func TestFormatHandler(t *testing.T) {
+	data := []byte(`[{"path":"/a","url":"https://example.com"}]`)
+	fallback := http.NotFoundHandler()
+
+	h, err := FormatHandler(data, "json", fallback)
+	if err != nil {
+		t.Fatalf("FormatHandler returned unexpected error: %v", err)
+	}
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rw.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusFound)
+	}
+	if got := rw.Header().Get("Location"); got != "https://example.com" {
+		t.Fatalf("Location = %q, want %q", got, "https://example.com")
+	}
+}
+
+func TestFormatHandler_UnknownFormat(t *testing.T) {
+	if _, err := FormatHandler([]byte(`[]`), "xml", http.NotFoundHandler()); err == nil {
+		t.Fatal("expected an error for an unregistered format, got nil")
+	}
+}
@@ -0,0 +1,118 @@
+package urlshort
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// fakeRedisServer answers just enough of the RESP2 protocol (GET only) to
+// exercise RedisHandler without a real Redis instance. It's driven over an
+// in-process net.Pipe rather than a TCP listener, one pipe per client
+// connection.
+type fakeRedisServer struct {
+	mu   sync.Mutex
+	data map[string]string
+	fail bool
+}
+
+func newFakeRedisClient(t *testing.T, data map[string]string) (*redis.Client, *fakeRedisServer) {
+	t.Helper()
+	srv := &fakeRedisServer{data: data}
+	client := redis.NewClient(&redis.Options{
+		Addr: "fakeredis",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			clientConn, serverConn := net.Pipe()
+			go srv.serve(serverConn)
+			return clientConn, nil
+		},
+	})
+	t.Cleanup(func() { client.Close() })
+	return client, srv
+}
+
+func (s *fakeRedisServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readRESPArray(r)
+		if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if strings.ToUpper(args[0]) != "GET" || len(args) != 2 {
+			fmt.Fprintf(conn, "-ERR fakeredis only supports GET, got %q\r\n", args)
+			continue
+		}
+		s.handleGet(conn, args[1])
+	}
+}
+
+func (s *fakeRedisServer) handleGet(conn net.Conn, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		fmt.Fprint(conn, "-ERR simulated connection failure\r\n")
+		return
+	}
+	v, ok := s.data[key]
+	if !ok {
+		fmt.Fprint(conn, "$-1\r\n")
+		return
+	}
+	fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(v), v)
+}
+
+// readRESPArray reads one RESP2 array-of-bulk-strings command, the only
+// shape a real redis.Client ever sends.
+func readRESPArray(r *bufio.Reader) ([]string, error) {
+	line, err := readRESPLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return nil, fmt.Errorf("fakeredis: expected array, got %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		bulkLine, err := readRESPLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(bulkLine) == 0 || bulkLine[0] != '$' {
+			return nil, fmt.Errorf("fakeredis: expected bulk string, got %q", bulkLine)
+		}
+		size, err := strconv.Atoi(bulkLine[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2) // payload plus trailing \r\n
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func readRESPLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
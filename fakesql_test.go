@@ -0,0 +1,109 @@
+package urlshort
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeSQLState backs a single sql.DB opened against the "fakesql" driver
+// registered below. It stands in for a real database so SQLHandler can be
+// tested without a network dependency: looking up path in rows mimics a
+// row existing for that path, and fail simulates a driver/connection error
+// distinct from "no rows".
+type fakeSQLState struct {
+	mu      sync.Mutex
+	rows    map[string]string
+	fail    bool
+	queries int32
+}
+
+func (s *fakeSQLState) queryCount() int32 { return atomic.LoadInt32(&s.queries) }
+
+var fakeSQLRegistry sync.Map // dsn string -> *fakeSQLState
+
+type fakeSQLDriver struct{}
+
+func (fakeSQLDriver) Open(dsn string) (driver.Conn, error) {
+	v, ok := fakeSQLRegistry.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("fakeSQLDriver: no state registered for dsn %q", dsn)
+	}
+	return &fakeSQLConn{state: v.(*fakeSQLState)}, nil
+}
+
+func init() {
+	sql.Register("fakesql", fakeSQLDriver{})
+}
+
+type fakeSQLConn struct {
+	state *fakeSQLState
+}
+
+func (c *fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fakeSQLConn: Prepare not supported, Query is used directly")
+}
+func (c *fakeSQLConn) Close() error { return nil }
+func (c *fakeSQLConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("fakeSQLConn: transactions not supported")
+}
+
+// Query implements the legacy driver.Queryer interface, which lets
+// database/sql issue a query without a Prepare round trip.
+func (c *fakeSQLConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	atomic.AddInt32(&c.state.queries, 1)
+
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	if c.state.fail {
+		return nil, fmt.Errorf("fakesql: simulated connection failure")
+	}
+	if len(args) != 1 {
+		return nil, fmt.Errorf("fakesql: expected 1 arg, got %d", len(args))
+	}
+	path, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("fakesql: expected string arg, got %T", args[0])
+	}
+	destination, ok := c.state.rows[path]
+	if !ok {
+		return &fakeSQLRows{}, nil
+	}
+	return &fakeSQLRows{values: []string{destination}}, nil
+}
+
+// fakeSQLRows holds at most a single "url" column row.
+type fakeSQLRows struct {
+	values []string
+	i      int
+}
+
+func (r *fakeSQLRows) Columns() []string { return []string{"url"} }
+func (r *fakeSQLRows) Close() error      { return nil }
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.values) {
+		return io.EOF
+	}
+	dest[0] = r.values[r.i]
+	r.i++
+	return nil
+}
+
+func newFakeSQLDB(t *testing.T, rows map[string]string) (*sql.DB, *fakeSQLState) {
+	t.Helper()
+	dsn := t.Name()
+	state := &fakeSQLState{rows: rows}
+	fakeSQLRegistry.Store(dsn, state)
+	t.Cleanup(func() { fakeSQLRegistry.Delete(dsn) })
+
+	db, err := sql.Open("fakesql", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, state
+}
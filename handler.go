@@ -2,27 +2,227 @@ package urlshort
 
 import (
 	"encoding/json"
-	"io/ioutil"
 	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/aabishkaryal/go-urlshortner/loader"
 	yaml "gopkg.in/yaml.v2"
 )
 
-// MapHandler will return an http.HandlerFunc (which also
-// implements http.Handler) that will attempt to map any
-// paths (keys in the map) to their corresponding URL (values
-// that each key in the map points to, in string format).
-// If the path is not provided in the map, then the fallback
+// HandlerOptions configures optional behavior shared by the handlers in
+// this package.
+type HandlerOptions struct {
+	// DefaultStatus is the HTTP redirect status used for a PathURL
+	// entry that does not specify its own Status. Defaults to
+	// http.StatusFound (302) when zero.
+	DefaultStatus int
+}
+
+func (o HandlerOptions) defaultStatus() int {
+	if o.DefaultStatus == 0 {
+		return http.StatusFound
+	}
+	return o.DefaultStatus
+}
+
+// MapHandler will return an http.Handler that will attempt to map any
+// paths (keys in the map) to their corresponding PathURL entries
+// (values that each key in the map points to).
+// If the path is not provided in the map, or the entry's Methods
+// don't include the request's method, then the fallback
 // http.Handler will be called instead.
-func MapHandler(pathsToUrls map[string]string, fallbackHandler http.Handler) http.HandlerFunc {
-	return func(rw http.ResponseWriter, r *http.Request) {
+//
+// An entry's Status controls the redirect status code sent for that
+// path, defaulting to opts' DefaultStatus (or http.StatusFound if opts
+// is omitted) when unset.
+//
+// The returned handler is wrapped with Instrument, so its redirects and
+// fallbacks count toward this package's metrics, access logs, and
+// Stats/AdminHandler the same as every other handler in this package.
+func MapHandler(pathsToUrls map[string]PathURL, fallbackHandler http.Handler, opts ...HandlerOptions) *InstrumentedHandler {
+	var options HandlerOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	resolve := func(rw http.ResponseWriter, r *http.Request) {
 		currentPath := r.URL.Path
-		if destination, ok := pathsToUrls[currentPath]; ok {
-			http.Redirect(rw, r, destination, http.StatusFound)
+		if entry, ok := pathsToUrls[currentPath]; ok && methodAllowed(entry.Methods, r.Method) {
+			status := entry.Status
+			if status == 0 {
+				status = options.defaultStatus()
+			}
+			http.Redirect(rw, r, entry.URL, status)
 			return
 		}
 		fallbackHandler.ServeHTTP(rw, r)
 	}
+	return InstrumentWithMappings(http.HandlerFunc(resolve), func() map[string]PathURL { return pathsToUrls })
+}
+
+// methodAllowed reports whether method is permitted by methods. An empty
+// methods list allows every method.
+func methodAllowed(methods []string, method string) bool {
+	if len(methods) == 0 {
+		return true
+	}
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// SourceHandler loads path-to-URL mappings from sourceURL and returns an
+// http.Handler that serves redirects from the resulting map, falling back
+// to fallbackHandler for any path it does not recognize.
+//
+// The scheme of sourceURL (file://, http(s)://, stdin://, ...) selects
+// which registered loader.Loader fetches the raw configuration; additional
+// schemes such as s3:// or consul:// can be supported by registering a
+// loader with loader.RegisterLoader. A bare path with no scheme, e.g.
+// "paths.json", is treated as a file:// source for backwards compatibility.
+//
+// The content format is selected by a "format" query parameter
+// (?format=json or ?format=yaml) or, failing that, by the source's file
+// extension, defaulting to JSON.
+//
+// If sourceURL carries a "refresh" query parameter set to a duration
+// understood by time.ParseDuration (e.g. "?refresh=30s"), SourceHandler
+// reloads the source on that interval and atomically swaps the in-memory
+// mapping. A failed reload is ignored and the last-good mapping keeps
+// serving.
+//
+// opts configures the default redirect status the same way it does for
+// MapHandler, applying to any PathURL entry that does not set its own
+// Status.
+//
+// The returned handler is wrapped with Instrument, so its redirects and
+// fallbacks count toward this package's metrics, access logs, and
+// Stats/AdminHandler. If sourceURL started a background refresh
+// goroutine, calling Close on the returned handler stops it.
+func SourceHandler(sourceURL string, fallbackHandler http.Handler, opts ...HandlerOptions) (*InstrumentedHandler, error) {
+	var options HandlerOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	data, u, err := loader.Load(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	format := formatFromSource(u)
+	pathURLs, err := decode(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &sourceHandler{fallback: fallbackHandler, opts: options, stop: make(chan struct{})}
+	h.set(pathURLs)
+
+	if refresh := u.Query().Get("refresh"); refresh != "" {
+		interval, err := time.ParseDuration(refresh)
+		if err != nil {
+			return nil, err
+		}
+		go h.watch(sourceURL, format, interval)
+	}
+	return InstrumentWithCloser(h, h.Mappings, h.Close), nil
+}
+
+// sourceHandler serves redirects from a mapping that may be swapped out
+// from under it by a background refresh goroutine.
+type sourceHandler struct {
+	mu          sync.RWMutex
+	pathsToURLs map[string]PathURL
+	fallback    http.Handler
+	opts        HandlerOptions
+	stop        chan struct{}
+	stopOnce    sync.Once
+}
+
+func (h *sourceHandler) set(pathURLs []PathURL) {
+	pathsToURLs := pathSliceToMapConversion(pathURLs)
+	h.mu.Lock()
+	h.pathsToURLs = pathsToURLs
+	h.mu.Unlock()
+}
+
+func (h *sourceHandler) watch(sourceURL, format string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			data, _, err := loader.Load(sourceURL)
+			if err != nil {
+				continue
+			}
+			pathURLs, err := decode(data, format)
+			if err != nil {
+				continue
+			}
+			h.set(pathURLs)
+		}
+	}
+}
+
+// Close stops the background refresh goroutine, if sourceURL's "refresh"
+// query parameter started one. It is safe to call even when no refresh
+// goroutine was started, and safe to call more than once.
+func (h *sourceHandler) Close() error {
+	h.stopOnce.Do(func() { close(h.stop) })
+	return nil
+}
+
+// Mappings returns the handler's current path-to-destination table, for
+// AdminHandler to include in its JSON body.
+func (h *sourceHandler) Mappings() map[string]PathURL {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	mappings := make(map[string]PathURL, len(h.pathsToURLs))
+	for path, entry := range h.pathsToURLs {
+		mappings[path] = entry
+	}
+	return mappings
+}
+
+func (h *sourceHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	entry, ok := h.pathsToURLs[r.URL.Path]
+	h.mu.RUnlock()
+	if ok && methodAllowed(entry.Methods, r.Method) {
+		status := entry.Status
+		if status == 0 {
+			status = h.opts.defaultStatus()
+		}
+		http.Redirect(rw, r, entry.URL, status)
+		return
+	}
+	h.fallback.ServeHTTP(rw, r)
+}
+
+// formatFromSource picks a content format for u, preferring an explicit
+// "format" query parameter and falling back to the URL's file extension.
+func formatFromSource(u *url.URL) string {
+	if format := u.Query().Get("format"); format != "" {
+		return format
+	}
+	p := u.Path
+	if u.Scheme == "file" {
+		// The relative "file://name.ext" form parses with the path in Host.
+		p = u.Host + u.Path
+	}
+	if ext := path.Ext(p); ext != "" {
+		return ext[1:]
+	}
+	return "json"
 }
 
 // JSONFileHandler will parse the provided JSON file and then return
@@ -34,30 +234,26 @@ func MapHandler(pathsToUrls map[string]string, fallbackHandler http.Handler) htt
 // JSON is expected to be in the format:
 //
 //	[
-// 		{path:pathValue, url:urlValue},
-// 		...
-// 	]
-//
-// The  errors that can be returned are related to having
-// invalid JSON data or opening json file.
-func JSONFileHandler(jsonFileName string, fallbackHandler http.Handler) (http.HandlerFunc, error) {
-	// 1. Read JSON file data
-	jsonData, err := openFile(jsonFileName)
-	if err != nil {
-		return nil, err
-	}
-	// 2. Parse JSON data to slice of pathURLs
-	pathURLs, err := parseJSONToPathURL(jsonData)
+//		{path:pathValue, url:urlValue},
+//		...
+//	]
+//
+// The file is watched, and edits to it are picked up without a process
+// restart; see WatchedFileHandler. The errors that can be returned are
+// related to having invalid JSON data or opening the json file.
+//
+// opts configures the default redirect status the same way it does for
+// MapHandler.
+func JSONFileHandler(jsonFileName string, fallbackHandler http.Handler, opts ...HandlerOptions) (http.HandlerFunc, error) {
+	h, err := newWatchedFileHandler(jsonFileName, "json", fallbackHandler, opts...)
 	if err != nil {
 		return nil, err
 	}
-	// 3. Convert slice of pathURLs to map
-	pathsToURLs := pathSliceToMapConversion(pathURLs)
-	return MapHandler(pathsToURLs, fallbackHandler), nil
+	return h.ServeHTTP, nil
 }
 
-func parseJSONToPathURL(data []byte) ([]pathURL, error) {
-	var pathsToURLs []pathURL
+func parseJSONToPathURL(data []byte) ([]PathURL, error) {
+	var pathsToURLs []PathURL
 	err := json.Unmarshal(data, &pathsToURLs)
 	if err != nil {
 		return nil, err
@@ -73,65 +269,56 @@ func parseJSONToPathURL(data []byte) ([]pathURL, error) {
 //
 // YAML is expected to be in the format:
 //
-// 	pathsToURLs:
-//     - path: /some-path
-//       url: https://www.some-url.com/demo
+//   - path: /some-path
+//     url: https://www.some-url.com/demo
 //
-// The  errors that can be returned are related to having
-// invalid YAML data or opening yaml file.
-func YAMLFileHandler(yamlFileName string, fallbackHandler http.HandlerFunc) (http.HandlerFunc, error) {
-	// 1. Open yaml file
-	yamlBytes, err := openFile(yamlFileName)
-	if err != nil {
-		return nil, err
-	}
-	return YAMLHandler(yamlBytes, fallbackHandler)
-}
-
-// openFile will open the file with fileName and return the content of the file.
-func openFile(fileName string) ([]byte, error) {
-	fileByte, err := ioutil.ReadFile(fileName)
+// The file is watched, and edits to it are picked up without a process
+// restart; see WatchedFileHandler. The errors that can be returned are
+// related to having invalid YAML data or opening the yaml file.
+//
+// opts configures the default redirect status the same way it does for
+// MapHandler.
+func YAMLFileHandler(yamlFileName string, fallbackHandler http.HandlerFunc, opts ...HandlerOptions) (http.HandlerFunc, error) {
+	h, err := newWatchedFileHandler(yamlFileName, "yaml", fallbackHandler, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return fileByte, nil
-
+	return h.ServeHTTP, nil
 }
 
 // YAMLHandler will parse the provided YAML and then return
-// an http.HandlerFunc (which also implements http.Handler)
-// that will attempt to map any paths to their corresponding
-// URL. If the path is not provided in the YAML, then the
+// an http.Handler that will attempt to map any paths to their
+// corresponding URL. If the path is not provided in the YAML, then the
 // fallback http.Handler will be called instead.
 //
 // YAML is expected to be in the format:
 //
-//     - path: /some-path
-//       url: https://www.some-url.com/demo
+//   - path: /some-path
+//     url: https://www.some-url.com/demo
 //
 // The only errors that can be returned all related to having
 // invalid YAML data.
-func YAMLHandler(yml []byte, fallbackHandler http.Handler) (http.HandlerFunc, error) {
+func YAMLHandler(yml []byte, fallbackHandler http.Handler) (http.Handler, error) {
 	// 1. Parse Yaml
 	pathURLs, err := parseYAMLToPathURL(yml)
 	if err != nil {
 		return nil, err
 	}
-	// 2. Convert slice of pathURL to map
+	// 2. Convert slice of PathURL to map
 	pathsToURLs := pathSliceToMapConversion(pathURLs)
 	return MapHandler(pathsToURLs, fallbackHandler), nil
 }
 
-func pathSliceToMapConversion(pUrls []pathURL) map[string]string {
-	result := make(map[string]string)
+func pathSliceToMapConversion(pUrls []PathURL) map[string]PathURL {
+	result := make(map[string]PathURL, len(pUrls))
 	for _, pU := range pUrls {
-		result[pU.Path] = pU.URL
+		result[pU.Path] = pU
 	}
 	return result
 }
 
-func parseYAMLToPathURL(data []byte) ([]pathURL, error) {
-	var pathURLs []pathURL
+func parseYAMLToPathURL(data []byte) ([]PathURL, error) {
+	var pathURLs []PathURL
 	err := yaml.Unmarshal(data, &pathURLs)
 	if err != nil {
 		return nil, err
@@ -139,7 +326,16 @@ func parseYAMLToPathURL(data []byte) ([]pathURL, error) {
 	return pathURLs, nil
 }
 
-type pathURL struct {
-	Path string `json:"path" yaml:"path"`
-	URL  string `json:"url" yaml:"url"`
+type PathURL struct {
+	Path string `json:"path" yaml:"path" toml:"path"`
+	URL  string `json:"url" yaml:"url" toml:"url"`
+	// Status is the HTTP redirect status to send for this entry, e.g.
+	// http.StatusMovedPermanently (301) or http.StatusPermanentRedirect
+	// (308). Zero means "use the handler's default", which is
+	// http.StatusFound (302) unless overridden by HandlerOptions.
+	Status int `json:"status,omitempty" yaml:"status,omitempty" toml:"status,omitempty"`
+	// Methods restricts this entry to the listed HTTP methods, e.g.
+	// ["GET", "HEAD"]. A request with another method falls through to
+	// the fallback handler. Empty means every method is allowed.
+	Methods []string `json:"methods,omitempty" yaml:"methods,omitempty" toml:"methods,omitempty"`
 }
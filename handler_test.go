@@ -0,0 +1,171 @@
+package urlshort
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMapHandler_DefaultStatus(t *testing.T) {
+	h := MapHandler(map[string]PathURL{
+		"/a": {Path: "/a", URL: "https://example.com/a"},
+	}, http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rw.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusFound)
+	}
+}
+
+func TestMapHandler_EntryStatusOverridesDefault(t *testing.T) {
+	h := MapHandler(map[string]PathURL{
+		"/a": {Path: "/a", URL: "https://example.com/a", Status: http.StatusMovedPermanently},
+	}, http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rw.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusMovedPermanently)
+	}
+}
+
+func TestMapHandler_OptionsDefaultStatus(t *testing.T) {
+	h := MapHandler(map[string]PathURL{
+		"/a": {Path: "/a", URL: "https://example.com/a"},
+	}, http.NotFoundHandler(), HandlerOptions{DefaultStatus: http.StatusPermanentRedirect})
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rw.Code != http.StatusPermanentRedirect {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusPermanentRedirect)
+	}
+}
+
+func TestMapHandler_MethodFiltering(t *testing.T) {
+	h := MapHandler(map[string]PathURL{
+		"/a": {Path: "/a", URL: "https://example.com/a", Methods: []string{"GET", "HEAD"}},
+	}, http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rw.Code != http.StatusFound {
+		t.Fatalf("GET: status = %d, want %d", rw.Code, http.StatusFound)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/a", nil))
+	if rw.Code != http.StatusNotFound {
+		t.Fatalf("POST: status = %d, want %d (fallback)", rw.Code, http.StatusNotFound)
+	}
+}
+
+func TestMapHandler_UnknownPathFallsThrough(t *testing.T) {
+	h := MapHandler(map[string]PathURL{}, http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rw.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusNotFound)
+	}
+}
+
+func TestSourceHandler_UnknownScheme(t *testing.T) {
+	if _, err := SourceHandler("s3://bucket/paths.json", http.NotFoundHandler()); err == nil {
+		t.Fatal("expected an error for an unregistered scheme, got nil")
+	}
+}
+
+func TestSourceHandler_FormatFromExtension(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "paths.yaml")
+	if err := os.WriteFile(path, []byte("- path: /a\n  url: https://example.com/a\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	h, err := SourceHandler("file://"+path, http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("SourceHandler returned unexpected error: %v", err)
+	}
+	defer h.Close()
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if got := rw.Header().Get("Location"); got != "https://example.com/a" {
+		t.Fatalf("Location = %q, want %q", got, "https://example.com/a")
+	}
+}
+
+func TestSourceHandler_FormatFromQueryOverridesExtension(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "paths.txt")
+	if err := os.WriteFile(path, []byte(`[{"path":"/a","url":"https://example.com/a"}]`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	h, err := SourceHandler("file://"+path+"?format=json", http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("SourceHandler returned unexpected error: %v", err)
+	}
+	defer h.Close()
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if got := rw.Header().Get("Location"); got != "https://example.com/a" {
+		t.Fatalf("Location = %q, want %q", got, "https://example.com/a")
+	}
+}
+
+func TestSourceHandler_BarePathBackCompat(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "paths.json")
+	if err := os.WriteFile(path, []byte(`[{"path":"/a","url":"https://example.com/a"}]`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	h, err := SourceHandler(path, http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("SourceHandler returned unexpected error: %v", err)
+	}
+	defer h.Close()
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if got := rw.Header().Get("Location"); got != "https://example.com/a" {
+		t.Fatalf("Location = %q, want %q", got, "https://example.com/a")
+	}
+}
+
+func TestSourceHandler_Refresh(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "paths.json")
+	if err := os.WriteFile(path, []byte(`[{"path":"/a","url":"https://first.example.com"}]`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	h, err := SourceHandler("file://"+path+"?refresh=20ms", http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("SourceHandler returned unexpected error: %v", err)
+	}
+	defer h.Close()
+
+	if err := os.WriteFile(path, []byte(`[{"path":"/a","url":"https://second.example.com"}]`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var lastLocation string
+	for time.Now().Before(deadline) {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+		lastLocation = rw.Header().Get("Location")
+		if lastLocation == "https://second.example.com" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("mapping was not refreshed within the deadline, last Location = %q", lastLocation)
+}
@@ -0,0 +1,41 @@
+package urlshort
+
+import (
+	"net/http"
+
+	"github.com/hashicorp/hcl"
+)
+
+// HCLHandler will parse the provided HCL and then return an
+// http.Handler that will attempt to map any paths to their
+// corresponding URL. If the path is not provided in the HCL, then the
+// fallback http.Handler will be called instead.
+//
+// HCL is expected to be in the format:
+//
+//	path "/some-path" {
+//	  url = "https://www.some-url.com/demo"
+//	}
+func HCLHandler(data []byte, fallbackHandler http.Handler) (http.Handler, error) {
+	return FormatHandler(data, "hcl", fallbackHandler)
+}
+
+func parseHCLToPathURL(data []byte) ([]PathURL, error) {
+	var doc struct {
+		Path map[string]struct {
+			URL string `hcl:"url"`
+		} `hcl:"path"`
+	}
+	if err := hcl.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	pathURLs := make([]PathURL, 0, len(doc.Path))
+	for path, entry := range doc.Path {
+		pathURLs = append(pathURLs, PathURL{Path: path, URL: entry.URL})
+	}
+	return pathURLs, nil
+}
+
+func init() {
+	RegisterDecoder("hcl", DecoderFunc(parseHCLToPathURL))
+}
@@ -0,0 +1,30 @@
+package loader
+
+import (
+	"io/ioutil"
+	"net/url"
+)
+
+// FileLoader reads configuration from the local filesystem. It handles
+// file:// source URLs as well as bare paths with no scheme at all, so
+// existing callers that pass a plain filename keep working unchanged.
+type FileLoader struct{}
+
+// Load implements Loader.
+func (FileLoader) Load(sourceURL *url.URL) ([]byte, error) {
+	return ioutil.ReadFile(filePath(sourceURL))
+}
+
+// filePath recovers the filesystem path from sourceURL. A relative form
+// like "file://config.json" (no leading slash) parses with the path in
+// Host, not Path; joining both handles that form as well as the
+// absolute "file:///abs/path" form and bare paths with no scheme at
+// all.
+func filePath(sourceURL *url.URL) string {
+	return sourceURL.Host + sourceURL.Path
+}
+
+func init() {
+	RegisterLoader("file", FileLoader{})
+	RegisterLoader("", FileLoader{})
+}
@@ -0,0 +1,38 @@
+package loader
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// HTTPLoader fetches configuration from a remote server. It handles
+// http:// and https:// source URLs.
+type HTTPLoader struct {
+	// Client is used to perform the request. If nil, http.DefaultClient
+	// is used.
+	Client *http.Client
+}
+
+// Load implements Loader.
+func (h HTTPLoader) Load(sourceURL *url.URL) ([]byte, error) {
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(sourceURL.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("loader: fetching %s: unexpected status %s", sourceURL, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func init() {
+	RegisterLoader("http", HTTPLoader{})
+	RegisterLoader("https", HTTPLoader{})
+}
@@ -0,0 +1,50 @@
+// Package loader provides pluggable loaders for fetching raw path-to-URL
+// configuration from different kinds of sources, selected by the scheme of
+// a source URL (file://, http(s)://, stdin://, ...).
+package loader
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Loader knows how to fetch raw configuration bytes for a source URL.
+type Loader interface {
+	Load(sourceURL *url.URL) ([]byte, error)
+}
+
+// LoaderFunc adapts an ordinary function to a Loader.
+type LoaderFunc func(sourceURL *url.URL) ([]byte, error)
+
+// Load implements Loader.
+func (f LoaderFunc) Load(sourceURL *url.URL) ([]byte, error) {
+	return f(sourceURL)
+}
+
+var loaders = map[string]Loader{}
+
+// RegisterLoader registers l to handle source URLs with the given scheme,
+// e.g. RegisterLoader("s3", S3Loader{}). Registering a scheme a second
+// time overwrites the previously registered loader.
+func RegisterLoader(scheme string, l Loader) {
+	loaders[scheme] = l
+}
+
+// Load parses sourceURL and dispatches to the Loader registered for its
+// scheme, returning the raw bytes it produces along with the parsed URL so
+// callers can inspect query parameters (e.g. ?format=).
+func Load(sourceURL string) ([]byte, *url.URL, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	l, ok := loaders[u.Scheme]
+	if !ok {
+		return nil, nil, fmt.Errorf("loader: no loader registered for scheme %q", u.Scheme)
+	}
+	data, err := l.Load(u)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, u, nil
+}
@@ -0,0 +1,61 @@
+package loader
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad_UnknownScheme(t *testing.T) {
+	if _, _, err := Load("s3://bucket/paths.json"); err == nil {
+		t.Fatal("expected an error for an unregistered scheme, got nil")
+	}
+}
+
+func TestLoad_RegisterLoader(t *testing.T) {
+	const scheme = "test-register"
+	RegisterLoader(scheme, LoaderFunc(func(u *url.URL) ([]byte, error) {
+		return []byte(u.Opaque), nil
+	}))
+
+	data, _, err := Load(scheme + ":payload")
+	if err != nil {
+		t.Fatalf("Load returned unexpected error: %v", err)
+	}
+	if string(data) != "payload" {
+		t.Fatalf("data = %q, want %q", data, "payload")
+	}
+}
+
+func TestFileLoader_RelativeAndAbsolutePaths(t *testing.T) {
+	dir := t.TempDir()
+	const want = `[{"path":"/a","url":"https://example.com"}]`
+	if err := os.WriteFile(filepath.Join(dir, "paths.json"), []byte(want), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	oldwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	defer os.Chdir(oldwd)
+
+	cases := []string{
+		"file://paths.json",                          // relative form: path lands in Host, not Path
+		"file://" + filepath.Join(dir, "paths.json"), // absolute triple-slash form
+		"paths.json",                                 // bare path, no scheme at all
+	}
+	for _, source := range cases {
+		data, _, err := Load(source)
+		if err != nil {
+			t.Fatalf("Load(%q) returned unexpected error: %v", source, err)
+		}
+		if string(data) != want {
+			t.Fatalf("Load(%q) = %q, want %q", source, data, want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package loader
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+)
+
+// StdinLoader reads configuration piped into the process's standard input.
+// It handles stdin:// source URLs, letting operators pass a mapping in at
+// startup without writing it to disk first, e.g. `cat paths.json |
+// myserver -source stdin://`.
+type StdinLoader struct{}
+
+// Load implements Loader.
+func (StdinLoader) Load(sourceURL *url.URL) ([]byte, error) {
+	return ioutil.ReadAll(os.Stdin)
+}
+
+func init() {
+	RegisterLoader("stdin", StdinLoader{})
+}
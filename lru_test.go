@@ -0,0 +1,52 @@
+package urlshort
+
+import "testing"
+
+func TestLRUCache_GetMiss(t *testing.T) {
+	c := newLRUCache(2)
+	if _, ok := c.get("missing"); ok {
+		t.Fatal("get on an empty cache returned ok=true")
+	}
+}
+
+func TestLRUCache_AddAndGet(t *testing.T) {
+	c := newLRUCache(2)
+	c.add("a", "https://example.com/a")
+
+	got, ok := c.get("a")
+	if !ok {
+		t.Fatal("get(a) returned ok=false after add")
+	}
+	if got != "https://example.com/a" {
+		t.Fatalf("get(a) = %q, want %q", got, "https://example.com/a")
+	}
+}
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRUCache(2)
+	c.add("a", "https://example.com/a")
+	c.add("b", "https://example.com/b")
+	c.get("a") // touch a, so b becomes the least recently used entry
+	c.add("c", "https://example.com/c")
+
+	if _, ok := c.get("b"); ok {
+		t.Fatal("get(b) returned ok=true, want b evicted as least recently used")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("get(a) returned ok=false, want a retained (it was touched most recently)")
+	}
+	if _, ok := c.get("c"); !ok {
+		t.Fatal("get(c) returned ok=false, want c retained (just added)")
+	}
+}
+
+func TestLRUCache_AddOverwritesExistingKey(t *testing.T) {
+	c := newLRUCache(2)
+	c.add("a", "https://example.com/a")
+	c.add("a", "https://example.com/a-v2")
+
+	got, ok := c.get("a")
+	if !ok || got != "https://example.com/a-v2" {
+		t.Fatalf("get(a) = (%q, %v), want (%q, true)", got, ok, "https://example.com/a-v2")
+	}
+}
@@ -19,9 +19,9 @@ func main() {
 
 	mux := defaultMux()
 	// Build the MapHandler using the mux as the fallback
-	pathsToUrls := map[string]string{
-		"/urlshort-godoc": "https://godoc.org/github.com/gophercises/urlshort",
-		"/yaml-godoc":     "https://godoc.org/gopkg.in/yaml.v2",
+	pathsToUrls := map[string]urlshort.PathURL{
+		"/urlshort-godoc": {Path: "/urlshort-godoc", URL: "https://godoc.org/github.com/gophercises/urlshort"},
+		"/yaml-godoc":     {Path: "/yaml-godoc", URL: "https://godoc.org/gopkg.in/yaml.v2"},
 	}
 	mapHandler := urlshort.MapHandler(pathsToUrls, mux)
 
@@ -0,0 +1,229 @@
+package urlshort
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// maxTrackedPaths bounds the number of distinct paths an InstrumentedHandler
+// will track individually, in its hits map and in the "path" label it feeds
+// to redirectsTotal. A wildcard PatternHandler mapping (or any handler whose
+// matched paths aren't drawn from a fixed set) would otherwise turn every
+// distinct request path into a permanent, unevictable entry in both — once
+// the cap is reached, any new path is folded into otherPathLabel instead.
+const maxTrackedPaths = 1000
+
+// otherPathLabel is the bucket newly-seen paths are folded into once an
+// InstrumentedHandler has already reached maxTrackedPaths distinct paths.
+const otherPathLabel = "other"
+
+// instrumentedContextKey marks a request as already counted by an
+// InstrumentedHandler, so a chain of instrumented handlers (the common
+// pattern of passing one constructor's result as another's
+// fallbackHandler, as main/main.go does) only records metrics, access
+// logs, and hits once per request, at the outermost handler.
+type instrumentedContextKey struct{}
+
+var (
+	redirectsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "urlshort_redirects_total",
+		Help: "Total number of redirects served, labeled by path and status.",
+	}, []string{"path", "status"})
+
+	fallbackTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "urlshort_fallback_total",
+		Help: "Total number of requests that fell through to the fallback handler.",
+	})
+
+	lookupDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name: "urlshort_lookup_duration_seconds",
+		Help: "Time spent resolving a request's destination before redirecting or falling through.",
+	})
+)
+
+// InstrumentedHandler wraps an http.Handler so every request through it
+// counts toward this package's Prometheus metrics, goes through a
+// structured access log, and is tallied in per-path hit counters —
+// regardless of which handler constructor (MapHandler, SourceHandler,
+// BoltHandler, RedisHandler, SQLHandler, PatternHandler, ...) produced
+// the wrapped handler. A response whose status is in the 3xx range is
+// treated as a redirect; anything else is treated as a fallback.
+//
+// Handler constructors in this package are routinely chained by passing
+// one's result as another's fallbackHandler (see main/main.go). When a
+// request falls through an outer InstrumentedHandler into an inner one,
+// only the outermost instance records metrics, logs, and hits for that
+// request; the inner instance detects this (via the request context) and
+// simply delegates, so a chain of any depth still counts once per request.
+type InstrumentedHandler struct {
+	next     http.Handler
+	mappings func() map[string]PathURL
+	closer   func() error
+
+	mu     sync.RWMutex
+	logger *slog.Logger
+
+	hitsMu sync.Mutex
+	hits   map[string]uint64
+}
+
+// Instrument wraps next so every request is counted, logged, and timed.
+func Instrument(next http.Handler) *InstrumentedHandler {
+	return InstrumentWithMappings(next, nil)
+}
+
+// InstrumentWithMappings is like Instrument, but also lets AdminHandler
+// report next's current path-to-destination table, for handlers (such as
+// the one returned by MapHandler or SourceHandler) that have one.
+// mappings may be nil if next has no mapping to report.
+func InstrumentWithMappings(next http.Handler, mappings func() map[string]PathURL) *InstrumentedHandler {
+	return InstrumentWithCloser(next, mappings, nil)
+}
+
+// InstrumentWithCloser is like InstrumentWithMappings, but also lets Close
+// release background resources next owns, such as SourceHandler's refresh
+// goroutine. closer may be nil if next has nothing to release.
+func InstrumentWithCloser(next http.Handler, mappings func() map[string]PathURL, closer func() error) *InstrumentedHandler {
+	return &InstrumentedHandler{next: next, mappings: mappings, closer: closer}
+}
+
+// ServeHTTP implements http.Handler. If r has already passed through
+// another InstrumentedHandler further out in the chain (detected via
+// instrumentedContextKey), this instance only delegates to next instead of
+// counting the request a second time.
+func (h *InstrumentedHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Context().Value(instrumentedContextKey{}) != nil {
+		h.next.ServeHTTP(rw, r)
+		return
+	}
+	r = r.WithContext(context.WithValue(r.Context(), instrumentedContextKey{}, true))
+
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: rw, status: http.StatusOK}
+	h.next.ServeHTTP(rec, r)
+	lookupDuration.Observe(time.Since(start).Seconds())
+
+	if rec.status >= 300 && rec.status < 400 {
+		label := h.recordHit(r.URL.Path)
+		redirectsTotal.WithLabelValues(label, strconv.Itoa(rec.status)).Inc()
+		h.log().Info("redirect", "path", r.URL.Path, "destination", rec.Header().Get("Location"), "status", rec.status)
+		return
+	}
+
+	fallbackTotal.Inc()
+	h.log().Info("fallback", "path", r.URL.Path, "method", r.Method)
+}
+
+// SetLogger overrides the structured logger ServeHTTP uses for access
+// logs. Without a call to SetLogger, the handler logs through
+// slog.Default().
+func (h *InstrumentedHandler) SetLogger(logger *slog.Logger) {
+	h.mu.Lock()
+	h.logger = logger
+	h.mu.Unlock()
+}
+
+func (h *InstrumentedHandler) log() *slog.Logger {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.logger != nil {
+		return h.logger
+	}
+	return slog.Default()
+}
+
+// recordHit tallies path in h.hits and returns the label it was actually
+// recorded under: path itself, unless h.hits already holds maxTrackedPaths
+// other distinct paths, in which case it falls back to otherPathLabel so
+// the map (and the Prometheus series it feeds) stays bounded.
+func (h *InstrumentedHandler) recordHit(path string) string {
+	h.hitsMu.Lock()
+	defer h.hitsMu.Unlock()
+	if h.hits == nil {
+		h.hits = make(map[string]uint64)
+	}
+	if _, ok := h.hits[path]; !ok && len(h.hits) >= maxTrackedPaths {
+		path = otherPathLabel
+	}
+	h.hits[path]++
+	return path
+}
+
+// Stats returns the number of times each path has been redirected since
+// the handler was created.
+func (h *InstrumentedHandler) Stats() map[string]uint64 {
+	h.hitsMu.Lock()
+	defer h.hitsMu.Unlock()
+	stats := make(map[string]uint64, len(h.hits))
+	for path, n := range h.hits {
+		stats[path] = n
+	}
+	return stats
+}
+
+// Close releases any background resources the wrapped handler owns (for
+// example, a SourceHandler's refresh goroutine when its source URL has a
+// "refresh" query parameter). It is a no-op if next doesn't own any.
+func (h *InstrumentedHandler) Close() error {
+	if h.closer == nil {
+		return nil
+	}
+	return h.closer()
+}
+
+// MetricsHandler returns an http.Handler that serves this package's
+// Prometheus metrics (urlshort_redirects_total, urlshort_fallback_total,
+// urlshort_lookup_duration_seconds) in the text exposition format,
+// suitable for mounting under /metrics.
+func (h *InstrumentedHandler) MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+// adminStats is the JSON body served by AdminHandler.
+type adminStats struct {
+	Mappings map[string]PathURL `json:"mappings,omitempty"`
+	Hits     map[string]uint64  `json:"hits"`
+}
+
+// AdminHandler returns an http.Handler, meant to be mounted at a prefix
+// of the caller's choosing, that serves the handler's hit counters (and,
+// if the wrapped handler has one, its current path-to-destination
+// mappings) as JSON, so operators can see which short links are actually
+// being used without scraping logs.
+func (h *InstrumentedHandler) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var mappings map[string]PathURL
+		if h.mappings != nil {
+			mappings = h.mappings()
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(adminStats{Mappings: mappings, Hits: h.Stats()})
+	})
+}
+
+// statusRecorder captures the status code written through it so
+// InstrumentedHandler can classify the response without knowing
+// anything about the handler that produced it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,52 @@
+package urlshort
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInstrumentedHandler_ChainedCountsOnce(t *testing.T) {
+	inner := MapHandler(map[string]PathURL{
+		"/b": {Path: "/b", URL: "https://example.com/b"},
+	}, http.NotFoundHandler())
+	outer := MapHandler(map[string]PathURL{
+		"/a": {Path: "/a", URL: "https://example.com/a"},
+	}, inner)
+
+	rw := httptest.NewRecorder()
+	outer.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/b", nil))
+	if rw.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusFound)
+	}
+
+	if got := outer.Stats()["/b"]; got != 1 {
+		t.Fatalf("outer.Stats()[/b] = %d, want 1", got)
+	}
+	if got := inner.Stats()["/b"]; got != 0 {
+		t.Fatalf("inner.Stats()[/b] = %d, want 0 (request should only be counted once, by the outermost handler)", got)
+	}
+}
+
+func TestInstrumentedHandler_CapsTrackedPaths(t *testing.T) {
+	mappings := make(map[string]PathURL, maxTrackedPaths+5)
+	for i := 0; i < maxTrackedPaths+5; i++ {
+		path := fmt.Sprintf("/p%d", i)
+		mappings[path] = PathURL{Path: path, URL: "https://example.com" + path}
+	}
+	h := MapHandler(mappings, http.NotFoundHandler())
+
+	for path := range mappings {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	stats := h.Stats()
+	if len(stats) > maxTrackedPaths+1 {
+		t.Fatalf("Stats() tracked %d distinct paths, want at most %d (including the overflow bucket)", len(stats), maxTrackedPaths+1)
+	}
+	if stats[otherPathLabel] == 0 {
+		t.Fatalf("expected overflow hits to be folded into %q, got %+v", otherPathLabel, stats)
+	}
+}
@@ -0,0 +1,132 @@
+package urlshort
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PatternMapping maps a path pattern to a destination template. A
+// pattern segment prefixed with ":" captures a single path segment by
+// name, and one prefixed with "*" captures the remainder of the path,
+// e.g. "/gh/:user/:repo" or "/docs/*rest". Captured names can be
+// referenced anywhere in Destination (including its query string) as
+// ":name", e.g. "https://github.com/:user/:repo".
+type PatternMapping struct {
+	Pattern     string
+	Destination string
+}
+
+// captureToken matches a ":name" or "*name" reference in a
+// PatternMapping's Destination template.
+var captureToken = regexp.MustCompile(`[:*](\w+)`)
+
+type compiledPattern struct {
+	destination  string
+	re           *regexp.Regexp
+	staticPrefix int
+}
+
+// PatternHandler compiles mappings once and returns an http.Handler that
+// matches r.URL.Path against them, substituting captured segments into
+// the destination (and appending the request's query string) before
+// redirecting, falling back to fallbackHandler when nothing matches.
+//
+// Mappings are evaluated with the longest static prefix winning first,
+// then in declaration order, so a more specific pattern such as
+// "/docs/api/*rest" takes priority over a catch-all like "/docs/*rest"
+// regardless of where each appears in mappings.
+//
+// The returned handler is wrapped with Instrument, so its redirects and
+// fallbacks count toward this package's metrics, access logs, and
+// Stats/AdminHandler.
+func PatternHandler(mappings []PatternMapping, fallbackHandler http.Handler) (*InstrumentedHandler, error) {
+	compiled := make([]*compiledPattern, 0, len(mappings))
+	for _, m := range mappings {
+		cp, err := compilePattern(m)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, cp)
+	}
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return compiled[i].staticPrefix > compiled[j].staticPrefix
+	})
+
+	resolve := func(rw http.ResponseWriter, r *http.Request) {
+		for _, cp := range compiled {
+			if destination, ok := cp.match(r.URL); ok {
+				http.Redirect(rw, r, destination, http.StatusFound)
+				return
+			}
+		}
+		fallbackHandler.ServeHTTP(rw, r)
+	}
+	return Instrument(http.HandlerFunc(resolve)), nil
+}
+
+// compilePattern turns m.Pattern into a regexp with a named group per
+// capture, and records the length of its leading static (capture-free)
+// prefix so PatternHandler can rank mappings by specificity.
+func compilePattern(m PatternMapping) (*compiledPattern, error) {
+	segments := strings.Split(strings.Trim(m.Pattern, "/"), "/")
+	var re strings.Builder
+	re.WriteString("^")
+	staticPrefix := 0
+	seenCapture := false
+	for i, seg := range segments {
+		if i > 0 {
+			re.WriteString("/")
+		}
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			seenCapture = true
+			re.WriteString("(?P<" + seg[1:] + ">[^/]+)")
+		case strings.HasPrefix(seg, "*"):
+			seenCapture = true
+			re.WriteString("(?P<" + seg[1:] + ">.*)")
+		default:
+			if !seenCapture {
+				staticPrefix += len(seg) + 1
+			}
+			re.WriteString(regexp.QuoteMeta(seg))
+		}
+	}
+	re.WriteString("$")
+	compiled, err := regexp.Compile(re.String())
+	if err != nil {
+		return nil, err
+	}
+	return &compiledPattern{destination: m.Destination, re: compiled, staticPrefix: staticPrefix}, nil
+}
+
+func (cp *compiledPattern) match(u *url.URL) (string, bool) {
+	matches := cp.re.FindStringSubmatch(strings.Trim(u.Path, "/"))
+	if matches == nil {
+		return "", false
+	}
+	captures := make(map[string]string, len(matches))
+	for i, name := range cp.re.SubexpNames() {
+		if i == 0 || name == "" {
+			continue
+		}
+		captures[name] = matches[i]
+	}
+
+	destination := captureToken.ReplaceAllStringFunc(cp.destination, func(token string) string {
+		if v, ok := captures[token[1:]]; ok {
+			return v
+		}
+		return token
+	})
+	if u.RawQuery != "" {
+		if strings.Contains(destination, "?") {
+			destination += "&" + u.RawQuery
+		} else {
+			destination += "?" + u.RawQuery
+		}
+	}
+	return destination, true
+}
@@ -0,0 +1,62 @@
+package urlshort
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPatternHandler_CaptureGroups(t *testing.T) {
+	h, err := PatternHandler([]PatternMapping{
+		{Pattern: "/gh/:user/:repo", Destination: "https://github.com/:user/:repo"},
+	}, http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("PatternHandler returned unexpected error: %v", err)
+	}
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/gh/alice/tools?tab=readme", nil))
+	if rw.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusFound)
+	}
+	want := "https://github.com/alice/tools?tab=readme"
+	if got := rw.Header().Get("Location"); got != want {
+		t.Fatalf("Location = %q, want %q", got, want)
+	}
+}
+
+func TestPatternHandler_WildcardSpecificityWins(t *testing.T) {
+	// "/docs/api/*rest" has a longer static prefix than "/docs/*rest",
+	// so it should win for paths both would otherwise match,
+	// regardless of declaration order.
+	h, err := PatternHandler([]PatternMapping{
+		{Pattern: "/docs/*rest", Destination: "https://fallback.example.com/:rest"},
+		{Pattern: "/docs/api/*rest", Destination: "https://api.example.com/:rest"},
+	}, http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("PatternHandler returned unexpected error: %v", err)
+	}
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/docs/api/v1/users", nil))
+	want := "https://api.example.com/v1/users"
+	if got := rw.Header().Get("Location"); got != want {
+		t.Fatalf("Location = %q, want %q", got, want)
+	}
+}
+
+func TestPatternHandler_NoMatchFallsThrough(t *testing.T) {
+	fallback := http.NotFoundHandler()
+	h, err := PatternHandler([]PatternMapping{
+		{Pattern: "/gh/:user/:repo", Destination: "https://github.com/:user/:repo"},
+	}, fallback)
+	if err != nil {
+		t.Fatalf("PatternHandler returned unexpected error: %v", err)
+	}
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/unmatched", nil))
+	if rw.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,46 @@
+package urlshort
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisCacheSize bounds the number of resolved lookups RedisHandler keeps
+// in memory to avoid a Redis round trip on every request.
+const redisCacheSize = 1024
+
+// RedisHandler returns an http.Handler that resolves r.URL.Path to a
+// destination by looking up keyPrefix+path in client, falling back to
+// fallbackHandler when the key is unset. Lookups are cached in an
+// in-memory LRU to bound per-request latency against the Redis round
+// trip. A lookup failure other than a missing key (e.g. a connection or
+// auth error) is logged through slog.Default() rather than silently
+// falling back, so a Redis outage shows up in logs instead of looking
+// like a 404.
+//
+// The returned handler is wrapped with Instrument, so its redirects and
+// fallbacks count toward this package's metrics, access logs, and
+// Stats/AdminHandler.
+func RedisHandler(client *redis.Client, keyPrefix string, fallbackHandler http.Handler) *InstrumentedHandler {
+	cache := newLRUCache(redisCacheSize)
+	resolve := func(rw http.ResponseWriter, r *http.Request) {
+		key := keyPrefix + r.URL.Path
+		if destination, ok := cache.get(key); ok {
+			http.Redirect(rw, r, destination, http.StatusFound)
+			return
+		}
+		destination, err := client.Get(r.Context(), key).Result()
+		if err == nil && destination != "" {
+			cache.add(key, destination)
+			http.Redirect(rw, r, destination, http.StatusFound)
+			return
+		}
+		if err != nil && err != redis.Nil {
+			slog.Default().Error("urlshort: redis lookup failed", "key", key, "error", err)
+		}
+		fallbackHandler.ServeHTTP(rw, r)
+	}
+	return Instrument(http.HandlerFunc(resolve))
+}
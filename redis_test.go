@@ -0,0 +1,44 @@
+package urlshort
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedisHandler_Hit(t *testing.T) {
+	client, _ := newFakeRedisClient(t, map[string]string{"short:/a": "https://example.com/a"})
+	h := RedisHandler(client, "short:", http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rw.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusFound)
+	}
+	if got := rw.Header().Get("Location"); got != "https://example.com/a" {
+		t.Fatalf("Location = %q, want %q", got, "https://example.com/a")
+	}
+}
+
+func TestRedisHandler_MissFallsThrough(t *testing.T) {
+	client, _ := newFakeRedisClient(t, map[string]string{"short:/a": "https://example.com/a"})
+	h := RedisHandler(client, "short:", http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rw.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusNotFound)
+	}
+}
+
+func TestRedisHandler_ErrorFallsThrough(t *testing.T) {
+	client, srv := newFakeRedisClient(t, map[string]string{"short:/a": "https://example.com/a"})
+	srv.fail = true
+	h := RedisHandler(client, "short:", http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rw.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d (a lookup error should fall through, not panic or 500)", rw.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,49 @@
+package urlshort
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// sqlCacheSize bounds the number of resolved lookups SQLHandler keeps in
+// memory to avoid a database round trip on every request.
+const sqlCacheSize = 1024
+
+// SQLHandler returns an http.Handler that resolves r.URL.Path to a
+// destination by running query against db with the path as its only
+// parameter, falling back to fallbackHandler when no row is returned.
+// query is expected to select a single column holding the destination
+// URL, e.g. "SELECT url FROM redirects WHERE path = ?". Lookups are
+// cached in an in-memory LRU to bound per-request latency against the
+// database round trip. A query failure other than sql.ErrNoRows (e.g. a
+// connection or driver error) is logged through slog.Default() rather
+// than silently falling back, so a database outage shows up in logs
+// instead of looking like a 404.
+//
+// The returned handler is wrapped with Instrument, so its redirects and
+// fallbacks count toward this package's metrics, access logs, and
+// Stats/AdminHandler.
+func SQLHandler(db *sql.DB, query string, fallbackHandler http.Handler) *InstrumentedHandler {
+	cache := newLRUCache(sqlCacheSize)
+	resolve := func(rw http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if destination, ok := cache.get(path); ok {
+			http.Redirect(rw, r, destination, http.StatusFound)
+			return
+		}
+		var destination string
+		err := db.QueryRowContext(r.Context(), query, path).Scan(&destination)
+		if err == nil && destination != "" {
+			cache.add(path, destination)
+			http.Redirect(rw, r, destination, http.StatusFound)
+			return
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			slog.Default().Error("urlshort: sql lookup failed", "path", path, "error", err)
+		}
+		fallbackHandler.ServeHTTP(rw, r)
+	}
+	return Instrument(http.HandlerFunc(resolve))
+}
@@ -0,0 +1,57 @@
+package urlshort
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSQLHandler_Hit(t *testing.T) {
+	db, _ := newFakeSQLDB(t, map[string]string{"/a": "https://example.com/a"})
+	h := SQLHandler(db, "SELECT url FROM redirects WHERE path = ?", http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rw.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusFound)
+	}
+	if got := rw.Header().Get("Location"); got != "https://example.com/a" {
+		t.Fatalf("Location = %q, want %q", got, "https://example.com/a")
+	}
+}
+
+func TestSQLHandler_MissFallsThrough(t *testing.T) {
+	db, _ := newFakeSQLDB(t, map[string]string{"/a": "https://example.com/a"})
+	h := SQLHandler(db, "SELECT url FROM redirects WHERE path = ?", http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rw.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rw.Code, http.StatusNotFound)
+	}
+}
+
+func TestSQLHandler_CachesLookups(t *testing.T) {
+	db, state := newFakeSQLDB(t, map[string]string{"/a": "https://example.com/a"})
+	h := SQLHandler(db, "SELECT url FROM redirects WHERE path = ?", http.NotFoundHandler())
+
+	for i := 0; i < 3; i++ {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	}
+	if got := state.queryCount(); got != 1 {
+		t.Fatalf("query count = %d, want 1 (later requests should be served from the cache)", got)
+	}
+}
+
+func TestSQLHandler_ErrorFallsThrough(t *testing.T) {
+	db, state := newFakeSQLDB(t, map[string]string{"/a": "https://example.com/a"})
+	state.fail = true
+	h := SQLHandler(db, "SELECT url FROM redirects WHERE path = ?", http.NotFoundHandler())
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if rw.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d (a query error should fall through, not panic or 500)", rw.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,35 @@
+package urlshort
+
+import (
+	"net/http"
+
+	"github.com/BurntSushi/toml"
+)
+
+// TOMLHandler will parse the provided TOML and then return an
+// http.Handler that will attempt to map any paths to their
+// corresponding URL. If the path is not provided in the TOML, then the
+// fallback http.Handler will be called instead.
+//
+// TOML is expected to be in the format:
+//
+//	[[paths]]
+//	path = "/some-path"
+//	url = "https://www.some-url.com/demo"
+func TOMLHandler(data []byte, fallbackHandler http.Handler) (http.Handler, error) {
+	return FormatHandler(data, "toml", fallbackHandler)
+}
+
+func parseTOMLToPathURL(data []byte) ([]PathURL, error) {
+	var doc struct {
+		Paths []PathURL `toml:"paths"`
+	}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Paths, nil
+}
+
+func init() {
+	RegisterDecoder("toml", DecoderFunc(parseTOMLToPathURL))
+}
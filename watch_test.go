@@ -0,0 +1,100 @@
+package urlshort
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile(%s): %v", path, err)
+	}
+}
+
+func TestWatchedFileHandler_Reload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "paths.json")
+	writeTestFile(t, path, `[{"path":"/a","url":"https://first.example.com"}]`)
+
+	h, err := WatchedFileHandler(path, http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("WatchedFileHandler returned unexpected error: %v", err)
+	}
+	defer h.Close()
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if got := rw.Header().Get("Location"); got != "https://first.example.com" {
+		t.Fatalf("Location = %q, want %q", got, "https://first.example.com")
+	}
+
+	writeTestFile(t, path, `[{"path":"/a","url":"https://second.example.com"}]`)
+	if err := h.Reload(); err != nil {
+		t.Fatalf("Reload returned unexpected error: %v", err)
+	}
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if got := rw.Header().Get("Location"); got != "https://second.example.com" {
+		t.Fatalf("Location = %q, want %q", got, "https://second.example.com")
+	}
+}
+
+func TestWatchedFileHandler_InvalidReloadKeepsLastGood(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "paths.json")
+	writeTestFile(t, path, `[{"path":"/a","url":"https://first.example.com"}]`)
+
+	h, err := WatchedFileHandler(path, http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("WatchedFileHandler returned unexpected error: %v", err)
+	}
+	defer h.Close()
+
+	writeTestFile(t, path, `not valid json`)
+	if err := h.Reload(); err == nil {
+		t.Fatal("expected Reload to return an error for invalid content, got nil")
+	}
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if got := rw.Header().Get("Location"); got != "https://first.example.com" {
+		t.Fatalf("Location = %q, want last-good mapping %q", got, "https://first.example.com")
+	}
+}
+
+// TestWatchedFileHandler_AutoReloadOnFileChange exercises the actual
+// fsnotify-driven path: unlike the tests above, it never calls Reload
+// itself, it only edits the file and waits for the background watch
+// goroutine to pick up the change on its own.
+func TestWatchedFileHandler_AutoReloadOnFileChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "paths.json")
+	writeTestFile(t, path, `[{"path":"/a","url":"https://first.example.com"}]`)
+
+	h, err := WatchedFileHandler(path, http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("WatchedFileHandler returned unexpected error: %v", err)
+	}
+	defer h.Close()
+
+	writeTestFile(t, path, `[{"path":"/a","url":"https://second.example.com"}]`)
+
+	deadline := time.Now().Add(5 * time.Second)
+	var lastLocation string
+	for time.Now().Before(deadline) {
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/a", nil))
+		lastLocation = rw.Header().Get("Location")
+		if lastLocation == "https://second.example.com" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("mapping was not auto-reloaded within the deadline, last Location = %q", lastLocation)
+}